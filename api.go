@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/birabittoh/tntsearch/sources"
+)
+
+// apiResult is a Torrent plus the fields clients actually want to render
+// or act on directly, so they don't have to re-derive a magnet link or a
+// human-readable size themselves.
+type apiResult struct {
+	Torrent
+	Magnet  string `json:"magnet"`
+	SizeFmt string `json:"size_fmt"`
+}
+
+func newAPIResult(t Torrent) apiResult {
+	return apiResult{
+		Torrent: t,
+		Magnet:  "magnet:?xt=urn:btih:" + t.Hash,
+		SizeFmt: sizeofFmt(t.Dimensione),
+	}
+}
+
+// apiEnvelope is the paginated response shape for /api.
+type apiEnvelope struct {
+	Total    int64       `json:"total"`
+	Page     int         `json:"page"`
+	PageSize int         `json:"page_size"`
+	Results  []apiResult `json:"results"`
+}
+
+const apiPageSize = 50
+
+// handleAPI returns a page of search results as a typed, paginated JSON
+// envelope. Unlike the old fmt.Sprintf-based implementation, encoding/json
+// correctly escapes backslashes, newlines and control characters in
+// titles/descriptions.
+func (a *App) handleAPI(w http.ResponseWriter, r *http.Request) {
+	keywords, category, page := getArgs(r)
+	sort := getSort(r)
+	source := getSource(r)
+
+	torrents, err := a.search(r, keywords, category, page, sort, source)
+	if err != nil {
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	// countTorrents only knows about the local SQLite table, so it can only
+	// stand in for "total" when results actually came from there. Remote/
+	// merged sources (source=torrentgalaxy, source=all) don't expose a real
+	// total, so fall back to the size of the page we actually got.
+	var total int64
+	if source == sources.LocalSourceName {
+		total, err = a.countTorrents(keywords, singleCategory(category))
+		if err != nil {
+			http.Error(w, "Database error", http.StatusInternalServerError)
+			return
+		}
+	} else {
+		total = int64(len(torrents))
+	}
+
+	results := make([]apiResult, 0, len(torrents))
+	for _, t := range torrents {
+		results = append(results, newAPIResult(t))
+	}
+
+	writeJSON(w, apiEnvelope{
+		Total:    total,
+		Page:     page,
+		PageSize: apiPageSize,
+		Results:  results,
+	})
+}
+
+// handleTorrentLookup implements GET /api/torrent/{hash}.
+func (a *App) handleTorrentLookup(w http.ResponseWriter, r *http.Request) {
+	hash := r.PathValue("hash")
+
+	var t Torrent
+	err := a.db.Where("hash = ?", hash).First(&t).Error
+	if err != nil {
+		http.Error(w, "Torrent not found", http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, newAPIResult(t))
+}
+
+type apiCategory struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+// handleCategories implements GET /api/categories, returning the known
+// categories with live counts from the torrents table.
+func (a *App) handleCategories(w http.ResponseWriter, r *http.Request) {
+	var counts []struct {
+		Categoria int
+		Count     int64
+	}
+	if err := a.db.Model(&Torrent{}).Select("categoria, count(*) as count").Group("categoria").Scan(&counts).Error; err != nil {
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	countByID := make(map[int]int64, len(counts))
+	for _, c := range counts {
+		countByID[c.Categoria] = c.Count
+	}
+
+	categories := make([]apiCategory, 0, len(categorie))
+	for id, name := range categorie {
+		categories = append(categories, apiCategory{ID: id, Name: name, Count: countByID[id]})
+	}
+
+	writeJSON(w, categories)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, "JSON encoding error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,44 @@
+package crawler
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Default category ids, matching the `categorie` map in package main.
+const (
+	CategoriaVarie   = 25
+	categoriaFilm    = 4
+	categoriaMusica  = 2
+	categoriaEBooks  = 3
+	categoriaWindows = 10
+)
+
+var extCategories = map[string]int{
+	".mkv":  categoriaFilm,
+	".mp4":  categoriaFilm,
+	".avi":  categoriaFilm,
+	".mp3":  categoriaMusica,
+	".flac": categoriaMusica,
+	".epub": categoriaEBooks,
+	".pdf":  categoriaEBooks,
+}
+
+// classifyCategory makes a best-effort guess at a category id from the
+// names of the files inside a torrent's metadata, falling back to
+// CategoriaVarie ("Varie") when nothing matches.
+func classifyCategory(filenames []string) int {
+	for _, name := range filenames {
+		ext := strings.ToLower(filepath.Ext(name))
+
+		if ext == ".iso" && strings.Contains(strings.ToLower(name), "win") {
+			return categoriaWindows
+		}
+
+		if cat, ok := extCategories[ext]; ok {
+			return cat
+		}
+	}
+
+	return CategoriaVarie
+}
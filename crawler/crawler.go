@@ -0,0 +1,209 @@
+// Package crawler implements an optional DHT crawler: it joins the
+// BitTorrent mainline DHT, harvests infohashes out of get_peers/
+// announce_peer traffic, fetches their metadata over the ut_metadata
+// extension (BEP-9) and hands finished Torrents to the caller for
+// persistence.
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/anacrolix/dht/v2"
+	"github.com/anacrolix/dht/v2/krpc"
+	"github.com/anacrolix/torrent"
+	"github.com/anacrolix/torrent/metainfo"
+	"github.com/birabittoh/tntsearch/models"
+)
+
+// MaxInFlightMetadata bounds how many metadata fetches run concurrently,
+// so a burst of announces doesn't open hundreds of outbound connections.
+const MaxInFlightMetadata = 32
+
+// Exists reports whether a torrent with the given infohash is already
+// indexed, used to dedup against existing Hash rows before spending a
+// metadata fetch on it.
+type Exists func(hash string) bool
+
+// Insert persists a freshly crawled torrent.
+type Insert func(models.Torrent) error
+
+type Crawler struct {
+	stats  Stats
+	exists Exists
+	insert Insert
+
+	dht    *dht.Server
+	client *torrent.Client
+
+	sem      chan struct{}
+	seen     chan string
+	inFlight sync.Map // hash -> struct{}, hashes queued or currently being fetched
+}
+
+func New(exists Exists, insert Insert) *Crawler {
+	return &Crawler{
+		exists: exists,
+		insert: insert,
+		sem:    make(chan struct{}, MaxInFlightMetadata),
+		seen:   make(chan string, 4096),
+	}
+}
+
+// Stats returns the live crawl counters.
+func (c *Crawler) Stats() StatsSnapshot {
+	return c.stats.Snapshot()
+}
+
+// Start joins the DHT and runs until ctx is canceled. It's meant to be
+// launched in its own goroutine from main(). Every get_peers query (via
+// OnQuery) and announce_peer query (via OnAnnouncePeer) the DHT server sees
+// is fed to offer(), the same approach used by passive DHT indexers like
+// magnetico.
+func (c *Crawler) Start(ctx context.Context) error {
+	cfg := dht.NewDefaultServerConfig()
+	cfg.OnQuery = func(query *krpc.Msg, source net.Addr) bool {
+		if query.Q == "get_peers" && query.A != nil {
+			c.offer(metainfo.Hash(query.A.InfoHash).HexString())
+		}
+		return true
+	}
+	cfg.OnAnnouncePeer = func(infoHash metainfo.Hash, ip net.IP, port int, portOk bool) {
+		c.offer(infoHash.HexString())
+	}
+
+	dhtServer, err := dht.NewServer(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to start DHT server: %w", err)
+	}
+	c.dht = dhtServer
+
+	torrentClient, err := torrent.NewClient(nil)
+	if err != nil {
+		return fmt.Errorf("failed to start torrent client: %w", err)
+	}
+	c.client = torrentClient
+
+	go c.keepBootstrapped(ctx)
+
+	for i := 0; i < MaxInFlightMetadata; i++ {
+		go c.metadataWorker(ctx)
+	}
+
+	<-ctx.Done()
+
+	torrentClient.Close()
+	dhtServer.Close()
+	return nil
+}
+
+// keepBootstrapped periodically re-bootstraps against the well-known DHT
+// routers so the routing table (and therefore the volume of announce
+// traffic we observe) stays healthy over a long-running crawl.
+func (c *Crawler) keepBootstrapped(ctx context.Context) {
+	ticker := time.NewTicker(15 * time.Minute)
+	defer ticker.Stop()
+
+	if _, err := c.dht.BootstrapContext(ctx); err != nil {
+		log.Printf("crawler: initial bootstrap failed: %v", err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := c.dht.BootstrapContext(ctx); err != nil {
+				log.Printf("crawler: bootstrap failed: %v", err)
+			}
+		}
+	}
+}
+
+// offer queues a newly seen infohash for metadata fetching, skipping
+// hashes we've already indexed or that are already queued/being fetched by
+// another worker. The LoadOrStore makes the "is this new" check and the
+// "claim it" step atomic, which plain exists-then-enqueue isn't: several
+// DHT peers can announce the same infohash within the same metadata-fetch
+// window, and up to MaxInFlightMetadata workers run concurrently.
+func (c *Crawler) offer(hash string) {
+	c.stats.hashesSeen.Add(1)
+
+	if c.exists(hash) {
+		return
+	}
+
+	if _, alreadyInFlight := c.inFlight.LoadOrStore(hash, struct{}{}); alreadyInFlight {
+		return
+	}
+
+	select {
+	case c.seen <- hash:
+	default:
+		c.inFlight.Delete(hash)
+		log.Printf("crawler: queue full, dropping hash %s", hash)
+	}
+}
+
+func (c *Crawler) metadataWorker(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case hash := <-c.seen:
+			c.fetchAndInsert(ctx, hash)
+		}
+	}
+}
+
+func (c *Crawler) fetchAndInsert(ctx context.Context, hash string) {
+	defer c.inFlight.Delete(hash)
+
+	c.sem <- struct{}{}
+	defer func() { <-c.sem }()
+
+	var ih metainfo.Hash
+	if err := ih.FromHexString(hash); err != nil {
+		return
+	}
+
+	t, _ := c.client.AddTorrentInfoHash(ih)
+	defer t.Drop()
+
+	select {
+	case <-t.GotInfo():
+	case <-ctx.Done():
+		return
+	case <-time.After(30 * time.Second):
+		return
+	}
+
+	c.stats.metadataFetched.Add(1)
+
+	info := t.Info()
+	var filenames []string
+	for _, f := range info.Files {
+		filenames = append(filenames, f.DisplayPath(info))
+	}
+	if len(filenames) == 0 {
+		filenames = []string{info.Name}
+	}
+
+	torrentRow := models.Torrent{
+		Data:       time.Now(),
+		Hash:       hash,
+		Titolo:     info.Name,
+		Dimensione: info.TotalLength(),
+		Categoria:  classifyCategory(filenames),
+	}
+
+	if err := c.insert(torrentRow); err != nil {
+		log.Printf("crawler: failed to insert %s: %v", hash, err)
+		return
+	}
+	c.stats.inserted.Add(1)
+}
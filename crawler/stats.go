@@ -0,0 +1,26 @@
+package crawler
+
+import "sync/atomic"
+
+// Stats tracks crawl progress for the /api/stats endpoint. All fields are
+// updated with atomic ops since they're read from an HTTP handler goroutine
+// while the crawler keeps writing to them.
+type Stats struct {
+	hashesSeen      atomic.Int64
+	metadataFetched atomic.Int64
+	inserted        atomic.Int64
+}
+
+type StatsSnapshot struct {
+	HashesSeen      int64 `json:"hashes_seen"`
+	MetadataFetched int64 `json:"metadata_fetched"`
+	Inserted        int64 `json:"inserted"`
+}
+
+func (s *Stats) Snapshot() StatsSnapshot {
+	return StatsSnapshot{
+		HashesSeen:      s.hashesSeen.Load(),
+		MetadataFetched: s.metadataFetched.Load(),
+		Inserted:        s.inserted.Load(),
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// ftsFields are the torrents_fts columns eligible for a `field:value` query
+// prefix.
+var ftsFields = map[string]bool{
+	"titolo":      true,
+	"descrizione": true,
+	"autore":      true,
+}
+
+// ensureFTS makes sure the torrents_fts FTS5 virtual table and its sync
+// triggers exist, rebuilding the index from the torrents table if it's
+// missing (e.g. first run after upgrading, or a fresh DB file).
+func (a *App) ensureFTS() error {
+	var count int64
+	if err := a.db.Raw(`SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = 'torrents_fts'`).Scan(&count).Error; err != nil {
+		return fmt.Errorf("failed to check for torrents_fts: %w", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	log.Println("torrents_fts index missing, building it now...")
+
+	stmts := []string{
+		`CREATE VIRTUAL TABLE torrents_fts USING fts5(titolo, descrizione, autore, content='torrents', content_rowid='id')`,
+		`CREATE TRIGGER torrents_ai AFTER INSERT ON torrents BEGIN
+			INSERT INTO torrents_fts(rowid, titolo, descrizione, autore) VALUES (new.id, new.titolo, new.descrizione, new.autore);
+		END`,
+		`CREATE TRIGGER torrents_ad AFTER DELETE ON torrents BEGIN
+			INSERT INTO torrents_fts(torrents_fts, rowid, titolo, descrizione, autore) VALUES ('delete', old.id, old.titolo, old.descrizione, old.autore);
+		END`,
+		`CREATE TRIGGER torrents_au AFTER UPDATE ON torrents BEGIN
+			INSERT INTO torrents_fts(torrents_fts, rowid, titolo, descrizione, autore) VALUES ('delete', old.id, old.titolo, old.descrizione, old.autore);
+			INSERT INTO torrents_fts(rowid, titolo, descrizione, autore) VALUES (new.id, new.titolo, new.descrizione, new.autore);
+		END`,
+	}
+	for _, stmt := range stmts {
+		if err := a.db.Exec(stmt).Error; err != nil {
+			return fmt.Errorf("failed to create torrents_fts schema: %w", err)
+		}
+	}
+
+	if err := a.db.Exec(`INSERT INTO torrents_fts(torrents_fts) VALUES ('rebuild')`).Error; err != nil {
+		return fmt.Errorf("failed to rebuild torrents_fts index: %w", err)
+	}
+
+	log.Println("torrents_fts index built")
+	return nil
+}
+
+type ftsToken struct {
+	text   string
+	negate bool
+	phrase bool
+}
+
+// tokenizeFTSQuery splits a raw user query into tokens, honouring quoted
+// phrases ("foo bar") and a leading "-" to negate a term.
+func tokenizeFTSQuery(raw string) []ftsToken {
+	var tokens []ftsToken
+	i, n := 0, len(raw)
+
+	for i < n {
+		for i < n && raw[i] == ' ' {
+			i++
+		}
+		if i >= n {
+			break
+		}
+
+		negate := false
+		if raw[i] == '-' {
+			negate = true
+			i++
+		}
+
+		var text string
+		phrase := false
+		if i < n && raw[i] == '"' {
+			phrase = true
+			j := i + 1
+			for j < n && raw[j] != '"' {
+				j++
+			}
+			text = raw[i+1 : min(j, n)]
+			if j < n {
+				j++
+			}
+			i = j
+		} else {
+			j := i
+			for j < n && raw[j] != ' ' {
+				j++
+			}
+			text = raw[i:j]
+			i = j
+		}
+
+		if text == "" {
+			continue
+		}
+		tokens = append(tokens, ftsToken{text: text, negate: negate, phrase: phrase})
+	}
+
+	return tokens
+}
+
+// quoteFTSValue wraps v in double quotes for use as an FTS5 string literal,
+// doubling any embedded quotes as required by the FTS5 query syntax.
+func quoteFTSValue(v string) string {
+	return `"` + strings.ReplaceAll(v, `"`, `""`) + `"`
+}
+
+// formatFTSTerm renders a token's text as an FTS5 term/phrase/column-filter,
+// without any NOT prefix — negation is handled separately by buildFTSQuery
+// since FTS5's NOT is a binary operator and needs a left-hand operand.
+func formatFTSTerm(tok ftsToken) string {
+	term := tok.text
+	if !tok.phrase {
+		if field, value, ok := strings.Cut(term, ":"); ok && ftsFields[field] {
+			return field + ":" + quoteFTSValue(value)
+		}
+	}
+	return quoteFTSValue(term)
+}
+
+// buildFTSQuery translates a user-facing query (quoted phrases, -exclude
+// terms, field:value prefixes) into FTS5 MATCH syntax. Negated terms are
+// appended as "NOT term" after at least one positive term, since FTS5
+// requires NOT to have a left-hand operand; a query made up entirely of
+// negated terms (e.g. "-foo") has no positive term to exclude from and is
+// therefore ignored rather than sent to SQLite as an invalid expression.
+func buildFTSQuery(raw string) string {
+	tokens := tokenizeFTSQuery(raw)
+
+	var positives, negatives []string
+	for _, tok := range tokens {
+		term := formatFTSTerm(tok)
+		if tok.negate {
+			negatives = append(negatives, term)
+		} else {
+			positives = append(positives, term)
+		}
+	}
+
+	if len(positives) == 0 {
+		return ""
+	}
+
+	query := strings.Join(positives, " ")
+	for _, neg := range negatives {
+		query += " NOT " + neg
+	}
+	return query
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenizeFTSQuery(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want []ftsToken
+	}{
+		{
+			name: "empty",
+			raw:  "",
+			want: nil,
+		},
+		{
+			name: "single term",
+			raw:  "ubuntu",
+			want: []ftsToken{{text: "ubuntu", negate: false, phrase: false}},
+		},
+		{
+			name: "multiple terms collapse whitespace",
+			raw:  "  ubuntu   linux ",
+			want: []ftsToken{
+				{text: "ubuntu", negate: false, phrase: false},
+				{text: "linux", negate: false, phrase: false},
+			},
+		},
+		{
+			name: "negated term",
+			raw:  "-windows",
+			want: []ftsToken{{text: "windows", negate: true, phrase: false}},
+		},
+		{
+			name: "quoted phrase",
+			raw:  `"debian stable"`,
+			want: []ftsToken{{text: "debian stable", negate: false, phrase: true}},
+		},
+		{
+			name: "negated quoted phrase",
+			raw:  `-"debian stable"`,
+			want: []ftsToken{{text: "debian stable", negate: true, phrase: true}},
+		},
+		{
+			name: "unterminated quote takes rest of string",
+			raw:  `"debian stable`,
+			want: []ftsToken{{text: "debian stable", negate: false, phrase: true}},
+		},
+		{
+			name: "field filter term",
+			raw:  "titolo:ubuntu",
+			want: []ftsToken{{text: "titolo:ubuntu", negate: false, phrase: false}},
+		},
+		{
+			name: "bare dash is dropped",
+			raw:  "- ubuntu",
+			want: []ftsToken{{text: "ubuntu", negate: false, phrase: false}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tokenizeFTSQuery(tt.raw)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tokenizeFTSQuery(%q) = %+v, want %+v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildFTSQuery(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{
+			name: "empty query",
+			raw:  "",
+			want: "",
+		},
+		{
+			name: "single term",
+			raw:  "ubuntu",
+			want: `"ubuntu"`,
+		},
+		{
+			name: "positive and negative term",
+			raw:  "ubuntu -windows",
+			want: `"ubuntu" NOT "windows"`,
+		},
+		{
+			name: "all negative terms have no left operand, so drop them all",
+			raw:  "-foo",
+			want: "",
+		},
+		{
+			name: "multiple negative terms with no positive term",
+			raw:  "-foo -bar",
+			want: "",
+		},
+		{
+			name: "quoted phrase",
+			raw:  `"debian stable"`,
+			want: `"debian stable"`,
+		},
+		{
+			name: "field filter",
+			raw:  "titolo:ubuntu",
+			want: `titolo:"ubuntu"`,
+		},
+		{
+			name: "unknown field falls back to a plain term",
+			raw:  "bogus:ubuntu",
+			want: `"bogus:ubuntu"`,
+		},
+		{
+			name: "embedded quotes are doubled",
+			raw:  `foo"bar`,
+			want: `"foo""bar"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := buildFTSQuery(tt.raw); got != tt.want {
+				t.Errorf("buildFTSQuery(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
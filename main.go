@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
 	"embed"
 	"encoding/csv"
+	"encoding/json"
 	"fmt"
 	"html/template"
 	"log"
@@ -12,6 +14,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/birabittoh/tntsearch/crawler"
+	"github.com/birabittoh/tntsearch/models"
+	"github.com/birabittoh/tntsearch/sources"
 	"github.com/glebarez/sqlite"
 	"github.com/joho/godotenv"
 	"gorm.io/gorm"
@@ -24,18 +29,11 @@ var (
 	templateFS embed.FS
 )
 
-type Torrent struct {
-	ID          uint      `gorm:"primaryKey" json:"id"`
-	Data        time.Time `gorm:"index" json:"data"`
-	Hash        string    `gorm:"index" json:"hash"`
-	Topic       string    `json:"topic"`
-	Post        string    `json:"post"`
-	Autore      string    `gorm:"index" json:"autore"`
-	Titolo      string    `gorm:"index" json:"titolo"`
-	Descrizione string    `json:"descrizione"`
-	Dimensione  int64     `json:"dimensione"`
-	Categoria   int       `gorm:"index" json:"categoria"`
-}
+// Torrent is an alias for models.Torrent so the rest of this package (and
+// its tests) can keep referring to the bare "Torrent" name even though the
+// type now lives in a shared package the sources/crawler subsystems can
+// also import.
+type Torrent = models.Torrent
 
 var categorie = map[int]string{
 	1:  "Film TV e programmi",
@@ -72,13 +70,28 @@ var categorie = map[int]string{
 var tableHeaders = []string{"DATA", "CATEGORIA", "TITOLO", "DESCRIZIONE", "AUTORE", "DIMENSIONE", "HASH"}
 
 type App struct {
-	db *gorm.DB
+	db         *gorm.DB
+	sourceMgr  *sources.Manager
+	dhtCrawler *crawler.Crawler
 }
 
 func NewApp(db *gorm.DB) *App {
 	return &App{db: db}
 }
 
+// defaultSource is used when the request omits source= entirely.
+const defaultSource = sources.LocalSourceName
+
+// getSource reads the source= query param ("local", "torrentgalaxy", "all",
+// ...), defaulting to the local store.
+func getSource(r *http.Request) string {
+	source := r.URL.Query().Get("source")
+	if source == "" {
+		return defaultSource
+	}
+	return source
+}
+
 func (a *App) loadCSVData(csvPath string) error {
 	file, err := os.Open(csvPath)
 	if err != nil {
@@ -162,23 +175,82 @@ func (a *App) loadCSVData(csvPath string) error {
 	return nil
 }
 
-func (a *App) searchTorrents(keywords string, category, page, pageSize int) ([]Torrent, error) {
+// validSorts are the values accepted by the sort= query param.
+var validSorts = map[string]bool{"relevance": true, "date": true, "size": true}
+
+func (a *App) searchTorrents(keywords string, categories []int, page, pageSize int, sort string) ([]Torrent, error) {
+	offset := (page - 1) * pageSize
+	return a.searchTorrentsOffset(keywords, categories, offset, pageSize, sort)
+}
+
+// searchTorrentsOffset is the offset/limit form of searchTorrents, used by
+// callers that don't think in terms of pages (e.g. the Torznab endpoint).
+// Keyword matching goes through the torrents_fts FTS5 index rather than a
+// LOWER(...) LIKE scan, so it also supports quoted phrases, -exclude terms
+// and field:value prefixes (see buildFTSQuery).
+func (a *App) searchTorrentsOffset(keywords string, categories []int, offset, limit int, sort string) ([]Torrent, error) {
 	var torrents []Torrent
-	query := a.db.Model(&Torrent{})
+	query, ftsApplied := a.searchQuery(keywords, categories)
+
+	switch sort {
+	case "size":
+		query = query.Order("torrents.dimensione DESC")
+	case "date":
+		query = query.Order("torrents.data DESC")
+	default: // "relevance", or no FTS match clause where relevance doesn't apply
+		if ftsApplied {
+			query = query.Order("bm25(torrents_fts)")
+		} else {
+			query = query.Order("torrents.data DESC")
+		}
+	}
+
+	// The explicit Select resolves the column ambiguity the torrents_fts
+	// join introduces (both tables have an "id" column) before Find scans
+	// rows into Torrent; countTorrents scans no rows and GORM's Count would
+	// render this Select into its COUNT expression, so it builds straight
+	// off searchQuery instead of through here.
+	err := query.Select("torrents.*").Limit(limit).Offset(offset).Find(&torrents).Error
 
+	return torrents, err
+}
+
+// searchQuery builds the shared, unordered WHERE clause for a search:
+// keyword matching against torrents_fts plus the category filter. Both
+// searchTorrentsOffset and countTorrents start from this so the count
+// respects exactly the same filters as the page of results it's paired
+// with. The returned bool reports whether the torrents_fts MATCH clause
+// was actually applied, since a keyword query that reduces to nothing but
+// negated terms (see buildFTSQuery) can't be expressed as one.
+func (a *App) searchQuery(keywords string, categories []int) (*gorm.DB, bool) {
+	query := a.db.Table("torrents")
+
+	ftsApplied := false
 	if keywords != "" {
-		kw := "%" + strings.ToLower(keywords) + "%"
-		query = query.Where("LOWER(titolo) LIKE ? OR LOWER(descrizione) LIKE ? OR LOWER(autore) LIKE ?", kw, kw, kw)
+		if ftsQuery := buildFTSQuery(keywords); ftsQuery != "" {
+			query = query.Joins("JOIN torrents_fts ON torrents_fts.rowid = torrents.id").
+				Where("torrents_fts MATCH ?", ftsQuery)
+			ftsApplied = true
+		}
 	}
 
-	if category != 0 {
-		query = query.Where("categoria = ?", category)
+	if len(categories) == 1 {
+		query = query.Where("torrents.categoria = ?", categories[0])
+	} else if len(categories) > 1 {
+		query = query.Where("torrents.categoria IN ?", categories)
 	}
 
-	offset := (page - 1) * pageSize
-	err := query.Order("data DESC").Limit(pageSize).Offset(offset).Find(&torrents).Error
+	return query, ftsApplied
+}
 
-	return torrents, err
+// countTorrents returns the total number of torrents matching the same
+// keywords/categories filters as searchTorrentsOffset, for pagination
+// metadata.
+func (a *App) countTorrents(keywords string, categories []int) (int64, error) {
+	var total int64
+	query, _ := a.searchQuery(keywords, categories)
+	err := query.Count(&total).Error
+	return total, err
 }
 
 func sizeofFmt(num int64) string {
@@ -221,10 +293,43 @@ func getArgs(r *http.Request) (string, int, int) {
 	return keywords, category, page
 }
 
+// getSort reads the sort= query param, defaulting to "relevance" for
+// unrecognized or missing values.
+func getSort(r *http.Request) string {
+	sort := r.URL.Query().Get("sort")
+	if !validSorts[sort] {
+		return "relevance"
+	}
+	return sort
+}
+
+// singleCategory wraps a single category id (0 meaning "any") into the
+// []int form expected by searchTorrents.
+func singleCategory(category int) []int {
+	if category == 0 {
+		return nil
+	}
+	return []int{category}
+}
+
+// search dispatches to the local FTS5 index directly (so sort= still
+// applies), or to the pluggable sources.Manager for remote/merged sources.
+func (a *App) search(r *http.Request, keywords string, category, page int, sort, source string) ([]Torrent, error) {
+	if source == sources.LocalSourceName {
+		return a.searchTorrents(keywords, singleCategory(category), page, 50, sort)
+	}
+	if a.sourceMgr == nil {
+		return a.searchTorrents(keywords, singleCategory(category), page, 50, sort)
+	}
+	return a.sourceMgr.Search(r.Context(), source, keywords, category, page)
+}
+
 func (a *App) handleMain(w http.ResponseWriter, r *http.Request) {
 	keywords, category, page := getArgs(r)
+	sort := getSort(r)
+	source := getSource(r)
 
-	torrents, err := a.searchTorrents(keywords, category, page, 50)
+	torrents, err := a.search(r, keywords, category, page, sort, source)
 	if err != nil {
 		http.Error(w, "Database error", http.StatusInternalServerError)
 		log.Printf("Database error: %v", err)
@@ -284,47 +389,20 @@ func (a *App) handleAPIHeader(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(response))
 }
 
-func (a *App) handleAPI(w http.ResponseWriter, r *http.Request) {
-	keywords, category, page := getArgs(r)
-
-	torrents, err := a.searchTorrents(keywords, category, page, 50)
-	if err != nil {
-		http.Error(w, "Database error", http.StatusInternalServerError)
-		log.Printf("Database error: %v", err)
-		return
-	}
-
+// handleStats reports DHT crawl progress. It responds with zeroed stats
+// (rather than an error) when the crawler isn't enabled, since that's a
+// normal deployment configuration, not a failure.
+func (a *App) handleStats(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	// Simple JSON serialization
-	var jsonResults []string
-	for _, t := range torrents {
-		jsonResult := fmt.Sprintf(`{
-			"data": "%s",
-			"hash": "%s",
-			"topic": "%s",
-			"post": "%s",
-			"autore": "%s",
-			"titolo": "%s",
-			"descrizione": "%s",
-			"dimensione": %d,
-			"categoria": %d
-		}`,
-			t.Data.Format("2006-01-02T15:04:05"),
-			t.Hash,
-			strings.ReplaceAll(t.Topic, `"`, `\"`),
-			strings.ReplaceAll(t.Post, `"`, `\"`),
-			strings.ReplaceAll(t.Autore, `"`, `\"`),
-			strings.ReplaceAll(t.Titolo, `"`, `\"`),
-			strings.ReplaceAll(t.Descrizione, `"`, `\"`),
-			t.Dimensione,
-			t.Categoria,
-		)
-		jsonResults = append(jsonResults, jsonResult)
+	var stats crawler.StatsSnapshot
+	if a.dhtCrawler != nil {
+		stats = a.dhtCrawler.Stats()
 	}
 
-	response := "[" + strings.Join(jsonResults, ",") + "]"
-	w.Write([]byte(response))
+	if err := json.NewEncoder(w).Encode(stats); err != nil {
+		log.Printf("Failed to encode stats: %v", err)
+	}
 }
 
 func main() {
@@ -367,6 +445,45 @@ func main() {
 
 	app := NewApp(db)
 
+	if err := app.ensureFTS(); err != nil {
+		log.Fatal("Failed to initialize FTS index:", err)
+	}
+
+	cacheTTL := sources.DefaultCacheTTL
+	if v := os.Getenv("SCRAPER_CACHE_TTL"); v != "" {
+		if parsed, err := time.ParseDuration(v); err == nil {
+			cacheTTL = parsed
+		} else {
+			log.Printf("Invalid SCRAPER_CACHE_TTL %q, using default: %v", v, err)
+		}
+	}
+
+	app.sourceMgr = sources.NewManager(
+		sources.NewCache(cacheTTL, sources.DefaultCacheSize),
+		sources.NewLocalSource(func(ctx context.Context, query string, category, page int) ([]models.Torrent, error) {
+			return app.searchTorrents(query, singleCategory(category), page, 50, "relevance")
+		}),
+		sources.NewTorrentGalaxySource(""),
+	)
+
+	if os.Getenv("DHT_ENABLED") == "true" {
+		app.dhtCrawler = crawler.New(
+			func(hash string) bool {
+				var count int64
+				db.Model(&Torrent{}).Where("hash = ?", hash).Count(&count)
+				return count > 0
+			},
+			func(t models.Torrent) error {
+				return db.Create(&t).Error
+			},
+		)
+		go func() {
+			if err := app.dhtCrawler.Start(context.Background()); err != nil {
+				log.Printf("DHT crawler stopped: %v", err)
+			}
+		}()
+	}
+
 	// Check if we need to load data
 	var count int64
 	db.Model(&Torrent{}).Count(&count)
@@ -383,6 +500,11 @@ func main() {
 	http.HandleFunc("/", app.handleMain)
 	http.HandleFunc("/api/header", app.handleAPIHeader)
 	http.HandleFunc("/api", app.handleAPI)
+	http.HandleFunc("/api/torznab", app.handleTorznab)
+	http.HandleFunc("/api/stats", app.handleStats)
+	http.HandleFunc("/api/categories", app.handleCategories)
+	http.HandleFunc("GET /api/torrent/{hash}", app.handleTorrentLookup)
+	http.HandleFunc("/rss", app.handleRSS)
 
 	log.Printf("Server starting on %s", address)
 	log.Fatal(http.ListenAndServe(address, nil))
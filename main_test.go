@@ -0,0 +1,108 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// newTestApp builds an App backed by a fresh in-memory SQLite database,
+// with the torrents_fts index created and seeded from fixtures.
+func newTestApp(t *testing.T, fixtures ...Torrent) *App {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open in-memory db: %v", err)
+	}
+	if err := db.AutoMigrate(&Torrent{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	app := NewApp(db)
+	if err := app.ensureFTS(); err != nil {
+		t.Fatalf("failed to build torrents_fts: %v", err)
+	}
+
+	for _, f := range fixtures {
+		if err := db.Create(&f).Error; err != nil {
+			t.Fatalf("failed to insert fixture %q: %v", f.Titolo, err)
+		}
+	}
+
+	return app
+}
+
+func testFixtures() []Torrent {
+	now := time.Now()
+	return []Torrent{
+		{Data: now, Hash: "hash1", Titolo: "Ubuntu Linux ISO", Descrizione: "distro", Categoria: 10},
+		{Data: now, Hash: "hash2", Titolo: "Debian Stable", Descrizione: "distro", Categoria: 10},
+		{Data: now, Hash: "hash3", Titolo: "Some Movie", Descrizione: "film", Categoria: 4},
+	}
+}
+
+func TestSearchTorrentsAndCountTorrents(t *testing.T) {
+	tests := []struct {
+		name       string
+		keywords   string
+		categories []int
+		wantTitles []string
+	}{
+		{
+			name:       "no filters returns everything",
+			wantTitles: []string{"Ubuntu Linux ISO", "Debian Stable", "Some Movie"},
+		},
+		{
+			name:       "keyword filters to matching rows",
+			keywords:   "ubuntu",
+			wantTitles: []string{"Ubuntu Linux ISO"},
+		},
+		{
+			name:       "category filters to matching rows",
+			categories: []int{4},
+			wantTitles: []string{"Some Movie"},
+		},
+		{
+			name:       "all-negative keyword query is ignored, not an error",
+			keywords:   "-ubuntu",
+			wantTitles: []string{"Ubuntu Linux ISO", "Debian Stable", "Some Movie"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApp(t, testFixtures()...)
+
+			torrents, err := app.searchTorrents(tt.keywords, tt.categories, 1, 50, "relevance")
+			if err != nil {
+				t.Fatalf("searchTorrents returned error: %v", err)
+			}
+			if len(torrents) != len(tt.wantTitles) {
+				t.Fatalf("searchTorrents returned %d rows, want %d (%v)", len(torrents), len(tt.wantTitles), torrents)
+			}
+
+			total, err := app.countTorrents(tt.keywords, tt.categories)
+			if err != nil {
+				t.Fatalf("countTorrents returned error: %v", err)
+			}
+			if total != int64(len(tt.wantTitles)) {
+				t.Errorf("countTorrents = %d, want %d", total, len(tt.wantTitles))
+			}
+		})
+	}
+}
+
+func TestCountTorrentsDoesNotInheritRowSelect(t *testing.T) {
+	app := newTestApp(t, testFixtures()...)
+
+	// A regression check for countTorrents reusing searchTorrentsOffset's
+	// row Select: GORM's Count() re-renders whatever Select was set on the
+	// builder, so a carried-over Select("torrents.*") produces the invalid
+	// "COUNT(`torrents`.`*`)" SQL instead of a plain count(*).
+	if _, err := app.countTorrents("", nil); err != nil {
+		t.Fatalf("countTorrents failed (likely reusing searchTorrentsOffset's row Select): %v", err)
+	}
+}
@@ -0,0 +1,19 @@
+// Package models holds the data types shared between the main tntsearch
+// application and its sources/crawler subsystems, which cannot import
+// package main directly.
+package models
+
+import "time"
+
+type Torrent struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Data        time.Time `gorm:"index" json:"data"`
+	Hash        string    `gorm:"index" json:"hash"`
+	Topic       string    `json:"topic"`
+	Post        string    `json:"post"`
+	Autore      string    `gorm:"index" json:"autore"`
+	Titolo      string    `gorm:"index" json:"titolo"`
+	Descrizione string    `json:"descrizione"`
+	Dimensione  int64     `json:"dimensione"`
+	Categoria   int       `gorm:"index" json:"categoria"`
+}
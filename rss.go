@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Type   string `xml:"type,attr"`
+	Length int64  `xml:"length,attr"`
+}
+
+type rssItem struct {
+	Title       string       `xml:"title"`
+	Description string       `xml:"description"`
+	Link        string       `xml:"link"`
+	GUID        string       `xml:"guid"`
+	PubDate     string       `xml:"pubDate"`
+	Enclosure   rssEnclosure `xml:"enclosure"`
+}
+
+type rssChannel struct {
+	Title       string    `xml:"title"`
+	Link        string    `xml:"link"`
+	Description string    `xml:"description"`
+	Items       []rssItem `xml:"item"`
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+// handleRSS returns an RSS 2.0 feed of the current search (same
+// keywords/category/page filters as handleMain), with a magnet enclosure
+// per item so feed readers and download managers can pick torrents up
+// directly.
+func (a *App) handleRSS(w http.ResponseWriter, r *http.Request) {
+	keywords, category, page := getArgs(r)
+	sort := getSort(r)
+
+	torrents, err := a.searchTorrents(keywords, singleCategory(category), page, 50, sort)
+	if err != nil {
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	channel := rssChannel{
+		Title:       "tntsearch",
+		Link:        "/rss",
+		Description: "tntsearch torrent feed",
+	}
+
+	for _, t := range torrents {
+		description := fmt.Sprintf("%s | %s | %s", sizeofFmt(t.Dimensione), t.Autore, categorie[t.Categoria])
+		channel.Items = append(channel.Items, rssItem{
+			Title:       t.Titolo,
+			Description: description,
+			Link:        "magnet:?xt=urn:btih:" + t.Hash,
+			GUID:        t.Hash,
+			PubDate:     t.Data.Format(time.RFC1123Z),
+			Enclosure: rssEnclosure{
+				URL:    "magnet:?xt=urn:btih:" + t.Hash,
+				Type:   "application/x-bittorrent",
+				Length: t.Dimensione,
+			},
+		})
+	}
+
+	writeXML(w, rssFeed{Version: "2.0", Channel: channel})
+}
@@ -0,0 +1,98 @@
+package sources
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/birabittoh/tntsearch/models"
+)
+
+// DefaultCacheTTL is used when SCRAPER_CACHE_TTL is unset or invalid.
+const DefaultCacheTTL = 5 * time.Minute
+
+// DefaultCacheSize bounds the number of distinct queries kept in memory.
+const DefaultCacheSize = 256
+
+type cacheEntry struct {
+	key     string
+	results []models.Torrent
+	expires time.Time
+}
+
+// Cache is a small bounded in-memory LRU used to avoid hammering remote
+// sources with repeated identical queries. Keys are expected to be built
+// with CacheKey.
+type Cache struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	maxItems int
+	order    *list.List
+	items    map[string]*list.Element
+}
+
+func NewCache(ttl time.Duration, maxItems int) *Cache {
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+	if maxItems <= 0 {
+		maxItems = DefaultCacheSize
+	}
+	return &Cache{
+		ttl:      ttl,
+		maxItems: maxItems,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+// CacheKey builds the cache key for a given query+category+page+source
+// combination.
+func CacheKey(query string, category, page int, source string) string {
+	return fmt.Sprintf("%s|%d|%d|%s", query, category, page, source)
+}
+
+func (c *Cache) Get(key string) ([]models.Torrent, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := elem.Value.(*cacheEntry)
+	if time.Now().After(entry.expires) {
+		c.order.Remove(elem)
+		delete(c.items, key)
+		return nil, false
+	}
+
+	c.order.MoveToFront(elem)
+	return entry.results, true
+}
+
+func (c *Cache) Set(key string, results []models.Torrent) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		elem.Value.(*cacheEntry).results = results
+		elem.Value.(*cacheEntry).expires = time.Now().Add(c.ttl)
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	entry := &cacheEntry{key: key, results: results, expires: time.Now().Add(c.ttl)}
+	elem := c.order.PushFront(entry)
+	c.items[key] = elem
+
+	if c.order.Len() > c.maxItems {
+		oldest := c.order.Back()
+		if oldest != nil {
+			c.order.Remove(oldest)
+			delete(c.items, oldest.Value.(*cacheEntry).key)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package sources
+
+import (
+	"context"
+
+	"github.com/birabittoh/tntsearch/models"
+)
+
+// LocalSourceName identifies the existing CSV/SQLite-backed store when
+// selected via the source= query parameter.
+const LocalSourceName = "local"
+
+// SearchFunc adapts the App's own searchTorrents to the Source interface
+// without sources needing to depend on gorm or the App type directly.
+type SearchFunc func(ctx context.Context, query string, category, page int) ([]models.Torrent, error)
+
+// LocalSource wraps the local SQLite/FTS5 index as a Source.
+type LocalSource struct {
+	search SearchFunc
+}
+
+func NewLocalSource(search SearchFunc) *LocalSource {
+	return &LocalSource{search: search}
+}
+
+func (s *LocalSource) Name() string {
+	return LocalSourceName
+}
+
+func (s *LocalSource) Search(ctx context.Context, query string, category, page int) ([]models.Torrent, error) {
+	return s.search(ctx, query, category, page)
+}
@@ -0,0 +1,82 @@
+package sources
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/birabittoh/tntsearch/models"
+)
+
+// All selects every registered source when passed as the source= value.
+const All = "all"
+
+// Manager fans a query out to one or more registered Sources, merging and
+// caching results from non-local (i.e. scraped) sources.
+type Manager struct {
+	sources map[string]Source
+	order   []string // registration order, so source=all merges deterministically
+	cache   *Cache
+}
+
+func NewManager(cache *Cache, registered ...Source) *Manager {
+	m := &Manager{sources: make(map[string]Source), cache: cache}
+	for _, s := range registered {
+		name := s.Name()
+		if _, exists := m.sources[name]; !exists {
+			m.order = append(m.order, name)
+		}
+		m.sources[name] = s
+	}
+	return m
+}
+
+// Search resolves `which` ("local", "torrentgalaxy", "all", ...) against the
+// registered sources and merges their results. Results from sources other
+// than the local store are cached by query+category+page+source.
+func (m *Manager) Search(ctx context.Context, which, query string, category, page int) ([]models.Torrent, error) {
+	names := m.resolve(which)
+	if len(names) == 0 {
+		return nil, fmt.Errorf("unknown source %q", which)
+	}
+
+	var merged []models.Torrent
+	for _, name := range names {
+		src := m.sources[name]
+
+		if name == LocalSourceName {
+			results, err := src.Search(ctx, query, category, page)
+			if err != nil {
+				return nil, err
+			}
+			merged = append(merged, results...)
+			continue
+		}
+
+		key := CacheKey(query, category, page, name)
+		if cached, ok := m.cache.Get(key); ok {
+			merged = append(merged, cached...)
+			continue
+		}
+
+		results, err := src.Search(ctx, query, category, page)
+		if err != nil {
+			return nil, fmt.Errorf("source %q: %w", name, err)
+		}
+		m.cache.Set(key, results)
+		merged = append(merged, results...)
+	}
+
+	return merged, nil
+}
+
+func (m *Manager) resolve(which string) []string {
+	if which == All {
+		// m.order (not ranging over m.sources) keeps source=all merges in a
+		// fixed, reproducible order across requests.
+		return m.order
+	}
+	if _, ok := m.sources[which]; ok {
+		return []string{which}
+	}
+	return nil
+}
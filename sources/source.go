@@ -0,0 +1,17 @@
+// Package sources implements the pluggable multi-source search framework:
+// a Source knows how to turn a query into a page of Torrents, whether that
+// means hitting the local SQLite index or scraping a remote site.
+package sources
+
+import (
+	"context"
+
+	"github.com/birabittoh/tntsearch/models"
+)
+
+// Source is anything that can answer a search query with a page of
+// torrents, local or remote.
+type Source interface {
+	Name() string
+	Search(ctx context.Context, query string, category, page int) ([]models.Torrent, error)
+}
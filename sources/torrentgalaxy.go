@@ -0,0 +1,147 @@
+package sources
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/birabittoh/tntsearch/models"
+)
+
+// TorrentGalaxySourceName identifies the TorrentGalaxy scraper when
+// selected via the source= query parameter.
+const TorrentGalaxySourceName = "torrentgalaxy"
+
+// torrentGalaxyCategories maps the subset of local categorie ids we can
+// confidently infer from a TorrentGalaxy listing's category column.
+var torrentGalaxyCategories = map[string]int{
+	"movies":   4,  // Film
+	"tv":       29, // Serie TV
+	"music":    2,  // Musica
+	"games":    11, // Pc Game
+	"ebooks":   3,  // E Books
+	"software": 10, // Windows Software
+}
+
+var hashRe = regexp.MustCompile(`(?i)btih:([a-f0-9]{40}|[a-z2-7]{32})`)
+
+// TorrentGalaxySource scrapes TorrentGalaxy's search results pages.
+type TorrentGalaxySource struct {
+	baseURL string
+	client  *http.Client
+}
+
+func NewTorrentGalaxySource(baseURL string) *TorrentGalaxySource {
+	if baseURL == "" {
+		baseURL = "https://torrentgalaxy.to"
+	}
+	return &TorrentGalaxySource{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+func (s *TorrentGalaxySource) Name() string {
+	return TorrentGalaxySourceName
+}
+
+func (s *TorrentGalaxySource) Search(ctx context.Context, query string, category, page int) ([]models.Torrent, error) {
+	searchURL := fmt.Sprintf("%s/torrents.php?search=%s&page=%d", s.baseURL, url.QueryEscape(query), page)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, searchURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build TorrentGalaxy request: %w", err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch TorrentGalaxy results: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("TorrentGalaxy returned status %d", resp.StatusCode)
+	}
+
+	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse TorrentGalaxy results: %w", err)
+	}
+
+	var torrents []models.Torrent
+	doc.Find("div.tgxtablerow").Each(func(_ int, row *goquery.Selection) {
+		anchor := row.Find("a.txlight")
+		title := strings.TrimSpace(anchor.Text())
+		if title == "" {
+			return
+		}
+
+		magnetHref, _ := row.Find(`a[href^="magnet:"]`).Attr("href")
+		hash := extractHash(magnetHref)
+		if hash == "" {
+			return
+		}
+
+		sizeText := strings.TrimSpace(row.Find("span.badge-secondary").First().Text())
+		uploader := strings.TrimSpace(row.Find("span.viewupload").Text())
+		categoryName := strings.ToLower(strings.TrimSpace(row.Find("div.tgxtablecell a[title]").First().AttrOr("title", "")))
+
+		categoria := torrentGalaxyCategories[categoryName]
+		if category != 0 && categoria != category {
+			return
+		}
+
+		torrents = append(torrents, models.Torrent{
+			Data:       time.Now(),
+			Hash:       hash,
+			Titolo:     title,
+			Autore:     uploader,
+			Dimensione: parseSize(sizeText),
+			Categoria:  categoria,
+		})
+	})
+
+	return torrents, nil
+}
+
+func extractHash(magnetURL string) string {
+	match := hashRe.FindStringSubmatch(magnetURL)
+	if len(match) < 2 {
+		return ""
+	}
+	return strings.ToUpper(match[1])
+}
+
+// parseSize turns a human size like "1.2 GB" into bytes, best-effort.
+func parseSize(text string) int64 {
+	fields := strings.Fields(text)
+	if len(fields) != 2 {
+		return 0
+	}
+
+	value, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0
+	}
+
+	multipliers := map[string]float64{
+		"B":  1,
+		"KB": 1 << 10,
+		"MB": 1 << 20,
+		"GB": 1 << 30,
+		"TB": 1 << 40,
+	}
+
+	mult, ok := multipliers[strings.ToUpper(fields[1])]
+	if !ok {
+		return 0
+	}
+
+	return int64(value * mult)
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"encoding/xml"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// torznabCategories maps the local `categorie` ids onto the Torznab
+// category numbering (see https://torznab.github.io/spec-1.3-draft/).
+// Categories with no obvious Torznab equivalent fall back to "Other" (8000).
+var torznabCategories = map[int]int{
+	4:  2000, // Film
+	29: 5000, // Serie TV
+	2:  3000, // Musica
+	11: 4000, // Pc Game
+}
+
+const torznabCategoryOther = 8000
+
+func categoryToTorznab(categoria int) int {
+	if id, ok := torznabCategories[categoria]; ok {
+		return id
+	}
+	return torznabCategoryOther
+}
+
+// localCategoriesForTorznab returns every local categoria id that maps to
+// the given Torznab category id, used to translate an incoming `cat=`
+// filter back into the `categoria` column.
+func localCategoriesForTorznab(torznabID int) []int {
+	var ids []int
+	for local := range categorie {
+		if categoryToTorznab(local) == torznabID {
+			ids = append(ids, local)
+		}
+	}
+	return ids
+}
+
+type torznabAttr struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type torznabItem struct {
+	Title string        `xml:"title"`
+	GUID  string        `xml:"guid"`
+	Link  string        `xml:"link"`
+	Attrs []torznabAttr `xml:"torznab:attr"`
+}
+
+type torznabChannel struct {
+	Title string        `xml:"title"`
+	Items []torznabItem `xml:"item"`
+}
+
+type torznabRSS struct {
+	XMLName   xml.Name       `xml:"rss"`
+	Version   string         `xml:"version,attr"`
+	XmlnsAtom string         `xml:"xmlns:atom,attr"`
+	XmlnsTz   string         `xml:"xmlns:torznab,attr"`
+	Channel   torznabChannel `xml:"channel"`
+}
+
+type torznabCapsCategory struct {
+	ID   int    `xml:"id,attr"`
+	Name string `xml:"name,attr"`
+}
+
+type torznabCaps struct {
+	XMLName xml.Name `xml:"caps"`
+	Server  struct {
+		Title   string `xml:"title,attr"`
+		Version string `xml:"version,attr"`
+	} `xml:"server"`
+	Searching struct {
+		Search struct {
+			Available       string `xml:"available,attr"`
+			SupportedParams string `xml:"supportedParams,attr"`
+		} `xml:"search"`
+		TVSearch struct {
+			Available       string `xml:"available,attr"`
+			SupportedParams string `xml:"supportedParams,attr"`
+		} `xml:"tv-search"`
+		MovieSearch struct {
+			Available       string `xml:"available,attr"`
+			SupportedParams string `xml:"supportedParams,attr"`
+		} `xml:"movie-search"`
+	} `xml:"searching"`
+	Categories struct {
+		Category []torznabCapsCategory `xml:"category"`
+	} `xml:"categories"`
+}
+
+func (a *App) writeTorznabCaps(w http.ResponseWriter) {
+	var caps torznabCaps
+	caps.Server.Title = "tntsearch"
+	caps.Server.Version = "1.0"
+	caps.Searching.Search.Available = "yes"
+	caps.Searching.Search.SupportedParams = "q"
+	caps.Searching.TVSearch.Available = "yes"
+	caps.Searching.TVSearch.SupportedParams = "q,cat"
+	caps.Searching.MovieSearch.Available = "yes"
+	caps.Searching.MovieSearch.SupportedParams = "q,cat"
+
+	seen := map[int]bool{torznabCategoryOther: true}
+	caps.Categories.Category = append(caps.Categories.Category, torznabCapsCategory{ID: torznabCategoryOther, Name: "Other"})
+	for local, tz := range torznabCategories {
+		if seen[tz] {
+			continue
+		}
+		seen[tz] = true
+		caps.Categories.Category = append(caps.Categories.Category, torznabCapsCategory{ID: tz, Name: categorie[local]})
+	}
+
+	writeXML(w, caps)
+}
+
+// handleTorznab exposes the torrent index as a Torznab-compatible
+// RSS/XML feed so indexer clients (Sonarr, Radarr, Prowlarr, ...) can
+// query it like any other tracker.
+func (a *App) handleTorznab(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	function := q.Get("t")
+
+	if function == "" || function == "caps" {
+		a.writeTorznabCaps(w)
+		return
+	}
+
+	keywords := q.Get("q")
+
+	var categories []int
+	for _, raw := range strings.Split(q.Get("cat"), ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		tz, err := strconv.Atoi(raw)
+		if err != nil {
+			continue
+		}
+		categories = append(categories, localCategoriesForTorznab(tz)...)
+	}
+
+	limit := 50
+	if v, err := strconv.Atoi(q.Get("limit")); err == nil && v > 0 {
+		limit = v
+	}
+
+	offset := 0
+	if v, err := strconv.Atoi(q.Get("offset")); err == nil && v >= 0 {
+		offset = v
+	}
+
+	torrents, err := a.searchTorrentsOffset(keywords, categories, offset, limit, "relevance")
+	if err != nil {
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	channel := torznabChannel{Title: "tntsearch"}
+	for _, t := range torrents {
+		item := torznabItem{
+			Title: t.Titolo,
+			GUID:  t.Hash,
+			Link:  "magnet:?xt=urn:btih:" + t.Hash,
+			Attrs: []torznabAttr{
+				{Name: "category", Value: strconv.Itoa(categoryToTorznab(t.Categoria))},
+				{Name: "size", Value: strconv.FormatInt(t.Dimensione, 10)},
+				{Name: "infohash", Value: t.Hash},
+			},
+		}
+		channel.Items = append(channel.Items, item)
+	}
+
+	rss := torznabRSS{
+		Version:   "2.0",
+		XmlnsAtom: "http://www.w3.org/2005/Atom",
+		XmlnsTz:   "http://torznab.com/schemas/2015/feed",
+		Channel:   channel,
+	}
+	writeXML(w, rss)
+}
+
+// writeXML encodes v as an XML document with the standard declaration,
+// used by both the Torznab and RSS endpoints.
+func writeXML(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.Write([]byte(xml.Header))
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		http.Error(w, "XML encoding error", http.StatusInternalServerError)
+	}
+}